@@ -1,6 +1,9 @@
 package minimize
 
-import "math"
+import (
+	"context"
+	"math"
+)
 
 type wrapper struct {
 	f func([]float64) float64
@@ -8,6 +11,8 @@ type wrapper struct {
 
 	cache []float64
 	tmp   []float64
+
+	evals int
 }
 
 func (w *wrapper) Get(x []float64) float64 {
@@ -42,6 +47,7 @@ search:
 	}
 
 	res := w.f(x)
+	w.evals++
 
 	copy(cache[stride:], cache[0:])
 	copy(cache[:n], x)
@@ -52,11 +58,66 @@ search:
 // Function finds an (approximate) local minimum of `f` near `x0`. The
 // parameter `ε` gives the size of the initial simplex.
 //
-// This is a wrapper around `Minimize()`, with caching of returned function
-// values to avoid unnecessary calls to `f`.
+// This is a thin wrapper around Default().Function(); use the Options type
+// directly to tune the algorithm's coefficients and stopping criteria.
 func Function(f func([]float64) float64, x0 []float64, ε float64) []float64 {
+	return Default().Function(f, x0, ε)
+}
+
+// Function finds an (approximate) local minimum of `f` near `x0`, using the
+// coefficients and limits in o.  The parameter `ε` gives the size of the
+// initial simplex.
+//
+// This runs the same search as `o.Minimize()`, with caching of returned
+// function values to avoid unnecessary calls to `f`.
+func (o *Options) Function(f func([]float64) float64, x0 []float64, ε float64) []float64 {
+	return o.FunctionFull(f, x0, ε).X
+}
+
+// FunctionFull works like Function, but returns a Result with diagnostic
+// information about the run instead of just the best point found.
+func (o *Options) FunctionFull(f func([]float64) float64, x0 []float64, ε float64) *Result {
+	return o.functionRun(context.Background(), f, x0, ε)
+}
+
+// FunctionContext works like FunctionFull, but checks ctx at the start of
+// every iteration.  If ctx is canceled, the search stops early, the
+// best-so-far vertex is returned, and the Result's Reason is set to
+// ReasonContextCanceled with Err holding ctx.Err().
+func (o *Options) FunctionContext(ctx context.Context, f func([]float64) float64, x0 []float64, ε float64) *Result {
+	return o.functionRun(ctx, f, x0, ε)
+}
+
+func (o *Options) functionRun(ctx context.Context, f func([]float64) float64, x0 []float64, ε float64) *Result {
 	w := &wrapper{f: f}
-	return Minimize(func(x, y []float64) bool {
+	res := o.run(ctx, func(x, y []float64) bool {
 		return w.Get(x) < w.Get(y)
-	}, x0, ε)
+	}, x0, ε, w)
+
+	res.Value = w.Get(res.X)
+
+	res.Values = make([]float64, len(res.Simplex))
+	for i, p := range res.Simplex {
+		res.Values[i] = w.Get(p)
+	}
+
+	res.FuncEvals = w.evals
+
+	return res
+}
+
+// FunctionFull works like Function, but returns a Result with diagnostic
+// information about the run instead of just the best point found.
+//
+// This is a thin wrapper around Default().FunctionFull().
+func FunctionFull(f func([]float64) float64, x0 []float64, ε float64) *Result {
+	return Default().FunctionFull(f, x0, ε)
+}
+
+// FunctionContext works like FunctionFull, but checks ctx at the start of
+// every iteration; see Options.FunctionContext for details.
+//
+// This is a thin wrapper around Default().FunctionContext().
+func FunctionContext(ctx context.Context, f func([]float64) float64, x0 []float64, ε float64) *Result {
+	return Default().FunctionContext(ctx, f, x0, ε)
 }
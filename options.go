@@ -0,0 +1,397 @@
+// seehuhn.de/go/minimize - the simplex algorithm of Nelder and Mead
+// Copyright (C) 2019  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minimize
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Options holds the coefficients and stopping criteria used by the
+// Nelder–Mead simplex algorithm.  Use Default to obtain the classical
+// coefficients of Lagarias et al.
+type Options struct {
+	// Reflection, Expansion, Contraction, and Shrinkage are the
+	// coefficients ρ, χ, γ, and σ used by the algorithm; see the
+	// package documentation for a reference.
+	Reflection  float64
+	Expansion   float64
+	Contraction float64
+	Shrinkage   float64
+
+	// MaxIterations bounds the number of iterations of the main loop.
+	MaxIterations int
+
+	// MaxFuncEvals bounds the number of calls made to the objective
+	// function.  A value of 0 means no limit.  The limit is checked at
+	// the start of each iteration, so the final count can exceed it by
+	// the calls made within one iteration.  It only applies to
+	// Function and its variants; Minimize and its variants ignore it.
+	MaxFuncEvals int
+
+	// XTolAbs and XTolRel give the absolute and relative tolerance on
+	// the spread of the simplex in x.  FTolAbs and FTolRel give the
+	// corresponding tolerances on the spread of the function values
+	// across the simplex.  The search stops once dx ≤ XTolAbs +
+	// XTolRel·‖p_0‖ and (if function values are available) df ≤
+	// FTolAbs + FTolRel·|f(p_0)|, where dx and df are the maximum
+	// distance and function value difference between the best vertex
+	// p_0 and the other vertices of the simplex.
+	XTolAbs, XTolRel float64
+	FTolAbs, FTolRel float64
+
+	// Adaptive selects the dimension-dependent coefficients of Gao and
+	// Han (2012) instead of Reflection, Expansion, Contraction, and
+	// Shrinkage: ρ = 1, χ = 1 + 2/n, γ = 0.75 − 1/(2n), σ = 1 − 1/n,
+	// where n is the dimension of the problem.  These degrade much more
+	// gracefully than the classical Lagarias et al. coefficients as n
+	// grows beyond about 10.
+	Adaptive bool
+
+	// InitialStep gives a per-coordinate step size for the initial
+	// simplex, overriding the scalar ε passed to Minimize/Function.  It
+	// is not used for the initial simplex if InitialSimplex is set, but
+	// restarts (see MaxRestarts) still use it.
+	InitialStep []float64
+
+	// RelativeInitialStep, if true and InitialStep and InitialSimplex
+	// are both unset, builds the initial step for coordinate i as
+	// max(nonzeroDelta*|x0_i|, zeroDelta), the construction used by
+	// SciPy and other common Nelder–Mead ports.  This copes much better
+	// than a fixed ε with coordinates on very different scales, and
+	// with x0_i == 0.
+	RelativeInitialStep bool
+
+	// InitialSimplex, if set, gives the n+1 vertices of the initial
+	// simplex directly, where n = len(x0); the values in x0, ε,
+	// InitialStep, and RelativeInitialStep are then not used to build
+	// the initial simplex, though restarts (see MaxRestarts) still use
+	// ε, InitialStep, and RelativeInitialStep.  Minimize and Function
+	// panic if InitialSimplex does not have n+1 vertices of length n.
+	// This is useful for restart strategies.
+	InitialSimplex [][]float64
+
+	// Callback, if non-nil, is invoked once per iteration with the
+	// iteration number and the best point and value found so far,
+	// including iterations that trigger a restart (see MaxRestarts); in
+	// that case best/bestVal are the values from just before the
+	// restart. If it returns a non-nil error, the search terminates
+	// early with ReasonCallbackError and that error stored in
+	// Result.Err.  bestVal is only meaningful for
+	// Function/FunctionFull/FunctionContext, which have a notion of
+	// function values; it is always 0 for Minimize and its variants.
+	Callback func(iter int, best []float64, bestVal float64) error
+
+	// MaxRestarts bounds how many times the simplex may be rebuilt
+	// around the current best vertex after the convergence test would
+	// otherwise have stopped the search.  Restarting is a common
+	// mitigation for Nelder–Mead's tendency to converge to
+	// non-stationary points.  A value of 0 (the default) disables
+	// restarts.
+	MaxRestarts int
+
+	// OrientedRestart, if true, rebuilds a restarted simplex from an
+	// orthogonal basis derived from the edges of the collapsed simplex,
+	// instead of from axis-aligned perturbations of the best vertex.
+	// This recovers convergence on problems like McKinnon's
+	// counterexample, where a plain axis-aligned restart still fails.
+	OrientedRestart bool
+}
+
+// nonzeroDelta and zeroDelta are the coefficients of the relative
+// initial-step construction selected by Options.RelativeInitialStep.
+const (
+	nonzeroDelta = 0.05
+	zeroDelta    = 0.00025
+)
+
+// Default returns the Options for the classical Nelder–Mead algorithm,
+// using the coefficients ρ=1, χ=2, γ=σ=0.5 from Lagarias et al.
+func Default() *Options {
+	return &Options{
+		Reflection:    1,
+		Expansion:     2,
+		Contraction:   0.5,
+		Shrinkage:     0.5,
+		MaxIterations: 100000,
+		XTolAbs:       1e-8,
+		FTolAbs:       1e-8,
+	}
+}
+
+// Minimize finds an (approximate) local minimum near `x0`, using the
+// coefficients and limits in o.  The parameter `ε` gives the size of the
+// initial simplex.
+func (o *Options) Minimize(less func(x, y []float64) bool, x0 []float64, ε float64) []float64 {
+	return o.MinimizeFull(less, x0, ε).X
+}
+
+// MinimizeFull works like Minimize, but returns a Result with diagnostic
+// information about the run instead of just the best point found.
+func (o *Options) MinimizeFull(less func(x, y []float64) bool, x0 []float64, ε float64) *Result {
+	return o.run(context.Background(), less, x0, ε, nil)
+}
+
+// MinimizeContext works like MinimizeFull, but checks ctx at the start of
+// every iteration.  If ctx is canceled, the search stops early, the
+// best-so-far vertex is returned, and the Result's Reason is set to
+// ReasonContextCanceled with Err holding ctx.Err().
+func (o *Options) MinimizeContext(ctx context.Context, less func(x, y []float64) bool, x0 []float64, ε float64) *Result {
+	return o.run(ctx, less, x0, ε, nil)
+}
+
+// checkConvergence reports whether the simplex s has converged, i.e.
+// whether the spread of its n+1 vertices around the best vertex p_0 (in
+// x, and, if w is non-nil, in function value) is within the tolerances
+// given by o.  When w is nil, no function values are available and only
+// the x-spread is tested.
+func (o *Options) checkConvergence(s *state, n int, w *wrapper) (bool, TerminationReason) {
+	p0 := s.Point(0)
+
+	xnorm := 0.0
+	for k := 0; k < n; k++ {
+		if v := math.Abs(p0[k]); v > xnorm {
+			xnorm = v
+		}
+	}
+	dx := 0.0
+	for i := 1; i <= n; i++ {
+		pi := s.Point(i)
+		for k := 0; k < n; k++ {
+			if d := math.Abs(pi[k] - p0[k]); d > dx {
+				dx = d
+			}
+		}
+	}
+	if dx > o.XTolAbs+o.XTolRel*xnorm {
+		return false, 0
+	}
+	if w == nil {
+		return true, ReasonConvergedX
+	}
+
+	f0 := w.Get(p0)
+	df := 0.0
+	for i := 1; i <= n; i++ {
+		if d := math.Abs(w.Get(s.Point(i)) - f0); d > df {
+			df = d
+		}
+	}
+	if df > o.FTolAbs+o.FTolRel*math.Abs(f0) {
+		return false, 0
+	}
+	return true, ReasonConvergedF
+}
+
+// initialStep returns the per-coordinate step vector used to build a
+// simplex around center, following whichever of InitialStep,
+// RelativeInitialStep, or the scalar ε is configured in o.  It does not
+// consider InitialSimplex, which bypasses this construction entirely.
+//
+// It panics if Options.InitialStep is set but does not have one entry
+// per coordinate of center.
+func (o *Options) initialStep(center []float64, ε float64) []float64 {
+	n := len(center)
+	step := make([]float64, n)
+	switch {
+	case o.InitialStep != nil:
+		if len(o.InitialStep) != n {
+			panic(fmt.Sprintf("minimize: len(Options.InitialStep) = %d, want %d", len(o.InitialStep), n))
+		}
+		copy(step, o.InitialStep)
+	case o.RelativeInitialStep:
+		for i, xi := range center {
+			step[i] = math.Max(nonzeroDelta*math.Abs(xi), zeroDelta)
+		}
+	default:
+		for i := range step {
+			step[i] = ε
+		}
+	}
+	return step
+}
+
+// run executes the Nelder–Mead main loop.  If w is non-nil, it gives
+// access to the cached objective function values; this is used by
+// FunctionFull/FunctionContext to enforce o.MaxFuncEvals, to test
+// convergence in f, and to pass a meaningful bestVal to o.Callback, none
+// of which plain Minimize can do since it only has a less predicate.
+func (o *Options) run(ctx context.Context, less func(x, y []float64) bool, x0 []float64, ε float64, w *wrapper) *Result {
+	n := len(x0)
+
+	// Allocate an array for the n+1 vertices of the simplex, together
+	// with three scratch vertices.
+	s := &state{
+		LessFn: less,
+		N:      n,
+		X:      make([]float64, (n+4)*n),
+	}
+	if o.InitialSimplex != nil {
+		if len(o.InitialSimplex) != n+1 {
+			panic(fmt.Sprintf("minimize: len(Options.InitialSimplex) = %d, want %d", len(o.InitialSimplex), n+1))
+		}
+		for i, v := range o.InitialSimplex {
+			if len(v) != n {
+				panic(fmt.Sprintf("minimize: len(Options.InitialSimplex[%d]) = %d, want %d", i, len(v), n))
+			}
+		}
+		s.InitSimplex(o.InitialSimplex)
+	} else {
+		s.Init(x0, o.initialStep(x0, ε))
+	}
+
+	ρ := o.Reflection
+	χ := o.Expansion
+	γ := o.Contraction
+	σ := o.Shrinkage
+	if o.Adaptive {
+		fn := float64(n)
+		ρ = 1
+		χ = 1 + 2/fn
+		γ = 0.75 - 1/(2*fn)
+		σ = 1 - 1/fn
+	}
+
+	res := &Result{Reason: ReasonMaxIterations}
+
+	shrinkCount := 0
+	step := 0
+	for ; step < o.MaxIterations; step++ {
+		if err := ctx.Err(); err != nil {
+			res.Reason = ReasonContextCanceled
+			res.Err = err
+			break
+		}
+
+		if w != nil && o.MaxFuncEvals > 0 && w.evals >= o.MaxFuncEvals {
+			res.Reason = ReasonMaxFuncEvals
+			break
+		}
+
+		if ok, reason := o.checkConvergence(s, n, w); ok {
+			if res.Restarts < o.MaxRestarts {
+				best := make([]float64, n)
+				copy(best, s.Point(0))
+				bestVal := 0.0
+				if w != nil {
+					bestVal = w.Get(best)
+				}
+
+				if o.OrientedRestart {
+					o.orientedRestart(s, n, ε)
+				} else {
+					center := make([]float64, n)
+					copy(center, s.Point(0))
+					s.Init(center, o.initialStep(center, ε))
+				}
+				res.Restarts++
+				shrinkCount = 0
+
+				if o.Callback != nil {
+					if err := o.Callback(step, best, bestVal); err != nil {
+						res.Reason = ReasonCallbackError
+						res.Err = err
+						step++
+						break
+					}
+				}
+				continue
+			}
+			res.Reason = reason
+			break
+		}
+
+		stalled := false
+
+		s.Centroid() // stored in p_{n+1}
+
+		s.Shift(n+2, n+1, n, -ρ) // reflect
+		winner := s.Less(n+2, 0)
+		switch {
+		case !winner && s.Less(n+2, n-1):
+			s.Insert(n+2, 1, n-1)
+			res.Reflections++
+
+		case winner:
+			s.Shift(n+3, n+1, n+2, χ) // expand
+			if s.Less(n+3, n+2) {
+				s.Insert(n+3, 0, 0)
+				shrinkCount = 0
+				res.Expansions++
+			} else {
+				s.Insert(n+2, 0, 0)
+				res.Reflections++
+			}
+
+		default:
+			if s.Less(n+2, n) {
+				s.Shift(n+2, n+1, n+2, γ) // outside contraction
+			} else {
+				s.Shift(n+2, n+1, n, γ) // inside contraction
+			}
+			if s.Less(n+2, n) {
+				s.Insert(n+2, 0, n)
+				res.Contractions++
+			} else {
+				s.Shrink(σ)
+				sort.Sort(s)
+				shrinkCount++
+				res.Shrinks++
+
+				if shrinkCount > 100 {
+					res.Reason = ReasonShrinkStall
+					stalled = true
+				}
+			}
+		}
+
+		if o.Callback != nil {
+			best := make([]float64, n)
+			copy(best, s.Point(0))
+			bestVal := 0.0
+			if w != nil {
+				bestVal = w.Get(best)
+			}
+			if err := o.Callback(step, best, bestVal); err != nil {
+				res.Reason = ReasonCallbackError
+				res.Err = err
+				step++
+				break
+			}
+		}
+
+		if stalled {
+			step++
+			break
+		}
+	}
+	res.Iterations = step
+
+	res.X = make([]float64, n)
+	copy(res.X, s.Point(0))
+
+	res.Simplex = make([][]float64, n+1)
+	for i := range res.Simplex {
+		p := make([]float64, n)
+		copy(p, s.Point(i))
+		res.Simplex[i] = p
+	}
+
+	return res
+}
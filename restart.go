@@ -0,0 +1,114 @@
+// seehuhn.de/go/minimize - the simplex algorithm of Nelder and Mead
+// Copyright (C) 2019  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minimize
+
+import (
+	"math"
+	"sort"
+)
+
+// orientedRestart rebuilds the simplex s around its current best vertex
+// p_0, using an orthogonal basis derived from the edges p_1-p_0, ...,
+// p_n-p_0 of the collapsed simplex instead of axis-aligned
+// perturbations.  This recovers convergence on problems where a plain
+// axis-aligned restart still fails, since it does not keep retrying
+// directions the simplex has already collapsed along.
+//
+// The Gram-Schmidt orthogonalization is carried out in coordinates
+// rescaled by Options.InitialStep/RelativeInitialStep, so that a
+// problem with badly-scaled axes still yields an orthonormal basis;
+// the result is then mapped back through the same per-coordinate scale
+// factors when the new vertices are built, so OrientedRestart does not
+// discard the step scaling the user configured.
+func (o *Options) orientedRestart(s *state, n int, ε float64) {
+	p0 := make([]float64, n)
+	copy(p0, s.Point(0))
+
+	step := o.initialStep(p0, ε)
+
+	edges := make([][]float64, n)
+	for i := 1; i <= n; i++ {
+		pi := s.Point(i)
+		e := make([]float64, n)
+		for k := 0; k < n; k++ {
+			e[k] = (pi[k] - p0[k]) / step[k]
+		}
+		edges[i-1] = e
+	}
+	basis := orthonormalBasis(n, edges)
+
+	copy(s.Point(0), p0)
+	for i := 1; i <= n; i++ {
+		pi := s.Point(i)
+		b := basis[i-1]
+		for k := 0; k < n; k++ {
+			pi[k] = p0[k] + step[k]*b[k]
+		}
+	}
+	sort.Sort(s)
+}
+
+// orthonormalBasis returns n orthonormal vectors in R^n, built from
+// vectors (which need not be independent or normalized) by Gram-Schmidt,
+// falling back to the standard basis to fill in any directions that
+// vectors fails to span.
+func orthonormalBasis(n int, vectors [][]float64) [][]float64 {
+	basis := make([][]float64, 0, n)
+	for _, v := range vectors {
+		if u, ok := orthogonalize(v, basis); ok {
+			basis = append(basis, u)
+		}
+	}
+	for k := 0; len(basis) < n; k++ {
+		e := make([]float64, n)
+		e[k] = 1
+		if u, ok := orthogonalize(e, basis); ok {
+			basis = append(basis, u)
+		}
+	}
+	return basis
+}
+
+// orthogonalize removes the components of v along each vector in basis
+// and normalizes the result.  It reports false if what remains is
+// negligible, i.e. v lies (numerically) in the span of basis already.
+func orthogonalize(v []float64, basis [][]float64) ([]float64, bool) {
+	u := make([]float64, len(v))
+	copy(u, v)
+	for _, b := range basis {
+		dot := dot(u, b)
+		for k := range u {
+			u[k] -= dot * b[k]
+		}
+	}
+	norm := math.Sqrt(dot(u, u))
+	if norm < 1e-10 {
+		return nil, false
+	}
+	for k := range u {
+		u[k] /= norm
+	}
+	return u, true
+}
+
+func dot(a, b []float64) float64 {
+	res := 0.0
+	for i := range a {
+		res += a[i] * b[i]
+	}
+	return res
+}
@@ -0,0 +1,122 @@
+// seehuhn.de/go/minimize - the simplex algorithm of Nelder and Mead
+// Copyright (C) 2019  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package minimize
+
+// TerminationReason indicates why a Minimize/Function run stopped.
+type TerminationReason int
+
+const (
+	// ReasonMaxIterations indicates that the run stopped because
+	// Options.MaxIterations was reached.
+	ReasonMaxIterations TerminationReason = iota
+
+	// ReasonMaxFuncEvals indicates that the run stopped because
+	// Options.MaxFuncEvals was reached.
+	ReasonMaxFuncEvals
+
+	// ReasonShrinkStall indicates that the run stopped because the
+	// simplex shrank more than 100 times without an intervening
+	// expansion step or restart.
+	ReasonShrinkStall
+
+	// ReasonConvergedX indicates that the run stopped because the
+	// spread of the simplex in x fell below the tolerance given by
+	// Options.XTolAbs and Options.XTolRel.
+	ReasonConvergedX
+
+	// ReasonConvergedF indicates that the run stopped because the
+	// spread of the function values across the simplex fell below the
+	// tolerance given by Options.FTolAbs and Options.FTolRel.
+	ReasonConvergedF
+
+	// ReasonContextCanceled indicates that the run stopped because the
+	// context passed to MinimizeContext/FunctionContext was canceled.
+	// Result.Err holds the context's error.
+	ReasonContextCanceled
+
+	// ReasonCallbackError indicates that the run stopped because
+	// Options.Callback returned a non-nil error.  Result.Err holds that
+	// error.
+	ReasonCallbackError
+)
+
+// String returns a short, human-readable name for r.
+func (r TerminationReason) String() string {
+	switch r {
+	case ReasonMaxIterations:
+		return "max-iter"
+	case ReasonMaxFuncEvals:
+		return "max-evals"
+	case ReasonShrinkStall:
+		return "shrink-stall"
+	case ReasonConvergedX:
+		return "converged-in-x"
+	case ReasonConvergedF:
+		return "converged-in-f"
+	case ReasonContextCanceled:
+		return "context-canceled"
+	case ReasonCallbackError:
+		return "callback-error"
+	default:
+		return "unknown"
+	}
+}
+
+// Result carries the outcome of a Minimize/Function run together with
+// diagnostic information about how the search proceeded.
+type Result struct {
+	// X is the best point found.
+	X []float64
+
+	// Value is f(X).  It is only populated by FunctionFull and
+	// FunctionContext, since MinimizeFull has no notion of function
+	// values.
+	Value float64
+
+	// Iterations is the number of iterations of the main loop.
+	Iterations int
+
+	// FuncEvals is the number of calls made to the objective function.
+	// It is only populated by FunctionFull and FunctionContext.
+	FuncEvals int
+
+	// Reflections, Expansions, Contractions, and Shrinks count how many
+	// iterations ended with the corresponding kind of step.
+	Reflections  int
+	Expansions   int
+	Contractions int
+	Shrinks      int
+
+	// Restarts counts how many times the simplex was rebuilt around the
+	// current best vertex; see Options.MaxRestarts.
+	Restarts int
+
+	// Reason gives the reason the run terminated.
+	Reason TerminationReason
+
+	// Err holds the error that caused termination, for
+	// ReasonContextCanceled and ReasonCallbackError.  It is nil
+	// otherwise.
+	Err error
+
+	// Simplex holds the final n+1 simplex vertices, sorted best first.
+	Simplex [][]float64
+
+	// Values holds f(Simplex[i]) for each i.  It is only populated by
+	// FunctionFull and FunctionContext.
+	Values []float64
+}
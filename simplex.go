@@ -17,21 +17,10 @@
 package minimize // import "seehuhn.de/go/minimize"
 
 import (
+	"context"
 	"sort"
 )
 
-// The following parameters refer to the description of the method in
-// Jeffrey C. Lagarias, James A. Reeds, Margaret H. Wright, and Paul
-// E. Wright: Convergence Properties of the Nelder-Mead Simplex Method
-// In Low Dimensions.  SIAM J. Optim, Vol. 9 (1998), No. 1,
-// pp. 112-147.  https://doi.org/10.1137/S1052623496303470
-const (
-	ρ = 1   // reflection parameter
-	χ = 2   // expansion parameter
-	γ = 0.5 // contraction parameter
-	σ = 0.5 // shrinkage parameter
-)
-
 type state struct {
 	LessFn func(x, y []float64) bool
 	N      int
@@ -63,17 +52,28 @@ func (s *state) Swap(i, j int) {
 	copy(xj, tmp)
 }
 
-func (s *state) Init(x []float64, ε float64) {
+// Init builds the initial simplex around x, perturbing coordinate k of
+// vertex k by step[k].
+func (s *state) Init(x []float64, step []float64) {
 	for k := 0; k <= s.N; k++ {
 		point := s.Point(k)
 		copy(point, x)
 		if k < s.N {
-			point[k] += ε
+			point[k] += step[k]
 		}
 	}
 	sort.Sort(s)
 }
 
+// InitSimplex sets the initial simplex directly from the given n+1
+// vertices, bypassing the perturbation done by Init.
+func (s *state) InitSimplex(vertices [][]float64) {
+	for k := 0; k <= s.N; k++ {
+		copy(s.Point(k), vertices[k])
+	}
+	sort.Sort(s)
+}
+
 // Insert point `src`, when we already know that the new position will
 // be one of p_i, ..., p_j.
 func (s *state) Insert(src, i, j int) {
@@ -124,7 +124,7 @@ func (s *state) Shift(a, b, c int, λ float64) {
 }
 
 // Move all points closer to p_0
-func (s *state) Shrink() {
+func (s *state) Shrink(σ float64) {
 	n := s.N
 	best := s.Point(0)
 	for k := 1; k <= n; k++ {
@@ -137,60 +137,25 @@ func (s *state) Shrink() {
 
 // Minimize finds an (approximate) local minimum near `x0`.  The parameter `ε`
 // gives the size of the initial simplex.
+//
+// This is a thin wrapper around Default().Minimize(); use the Options type
+// directly to tune the algorithm's coefficients and stopping criteria.
 func Minimize(less func(x, y []float64) bool, x0 []float64, ε float64) []float64 {
-	n := len(x0)
-
-	// Allocate an array for the n+1 vertices of the simplex, together
-	// with three scratch vertices.
-	s := &state{
-		LessFn: less,
-		N:      n,
-		X:      make([]float64, (n+4)*n),
-	}
-	s.Init(x0, ε)
-
-	shrinkCount := 0
-	for step := 0; step < 100000; step++ {
-		s.Centroid() // stored in p_{n+1}
-
-		s.Shift(n+2, n+1, n, -ρ) // reflect
-		winner := s.Less(n+2, 0)
-		if !winner && s.Less(n+2, n-1) {
-			s.Insert(n+2, 1, n-1)
-			continue
-		}
-
-		if winner {
-			s.Shift(n+3, n+1, n+2, χ) // expand
-			if s.Less(n+3, n+2) {
-				s.Insert(n+3, 0, 0)
-				shrinkCount = 0
-			} else {
-				s.Insert(n+2, 0, 0)
-			}
-			continue
-		}
-
-		if s.Less(n+2, n) {
-			s.Shift(n+2, n+1, n+2, γ) // outside contraction
-		} else {
-			s.Shift(n+2, n+1, n, γ) // inside contraction
-		}
-		if s.Less(n+2, n) {
-			s.Insert(n+2, 0, n)
-			continue
-		}
-
-		s.Shrink()
-		sort.Sort(s)
-		shrinkCount++
+	return Default().Minimize(less, x0, ε)
+}
 
-		if shrinkCount > 100 {
-			break
-		}
-	}
+// MinimizeFull works like Minimize, but returns a Result with diagnostic
+// information about the run instead of just the best point found.
+//
+// This is a thin wrapper around Default().MinimizeFull().
+func MinimizeFull(less func(x, y []float64) bool, x0 []float64, ε float64) *Result {
+	return Default().MinimizeFull(less, x0, ε)
+}
 
-	res := make([]float64, n)
-	copy(res, s.Point(0))
-	return res
+// MinimizeContext works like MinimizeFull, but checks ctx at the start of
+// every iteration; see Options.MinimizeContext for details.
+//
+// This is a thin wrapper around Default().MinimizeContext().
+func MinimizeContext(ctx context.Context, less func(x, y []float64) bool, x0 []float64, ε float64) *Result {
+	return Default().MinimizeContext(ctx, less, x0, ε)
 }